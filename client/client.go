@@ -44,7 +44,7 @@ func main() {
 func runClient(id int, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	url := "ws://localhost:8080/ws"
+	url := "ws://localhost:8080/ws/bench"
 	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
 	if err != nil {
 		log.Printf("client %d dial error: %v\n", id, err)
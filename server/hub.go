@@ -0,0 +1,83 @@
+// ============================================
+// In-memory pub/sub hub
+//
+// Backs GET /ws/:topic?subscribe=1: once a batch has been durably written
+// by a sink, it is fanned out to any subscribers of that topic so the
+// benchmark exercises ingestion-plus-fanout rather than write-only traffic.
+// ============================================
+
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gofiber/websocket/v2"
+)
+
+type hub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan Message]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subs: make(map[string]map[chan Message]struct{})}
+}
+
+// subscribe registers a new subscriber for topic and returns its channel
+// along with an unsubscribe func that must be called when the subscriber
+// disconnects.
+func (h *hub) subscribe(topic string) (chan Message, func()) {
+	ch := make(chan Message, 256)
+
+	h.mu.Lock()
+	if h.subs[topic] == nil {
+		h.subs[topic] = make(map[chan Message]struct{})
+	}
+	h.subs[topic][ch] = struct{}{}
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		delete(h.subs[topic], ch)
+		h.mu.Unlock()
+	}
+	return ch, unsubscribe
+}
+
+// publish fans msgs out to every current subscriber of topic. A message
+// that doesn't fit in a slow subscriber's buffer is dropped for that
+// subscriber rather than blocking ingestion; the subscriber stays registered.
+func (h *hub) publish(topic string, msgs []Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[topic] {
+		for _, m := range msgs {
+			select {
+			case ch <- m:
+			default:
+			}
+		}
+	}
+}
+
+var messageHub = newHub()
+
+// serveSubscriber writes every message published to topic out to c until
+// ctx is cancelled or the connection errors. It backs GET /ws/:topic?subscribe=1.
+func serveSubscriber(ctx context.Context, c *websocket.Conn, topic string) {
+	ch, unsubscribe := messageHub.subscribe(topic)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m := <-ch:
+			if err := c.WriteMessage(websocket.TextMessage, []byte(m.Payload)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// ============================================
+// Prometheus metrics
+//
+// Exposes /metrics so naive/buffered/bulk/copy can be compared with
+// time-series data (ingest rate, insert latency, batch sizes, channel
+// occupancy, connection count) instead of the single wall-clock number
+// logged at completion. Everything is labeled by mode so one Grafana
+// dashboard can overlay all the runs.
+// ============================================
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/adaptor/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	messagesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ws_messages_received_total",
+		Help: "Messages read off websocket connections.",
+	}, []string{"mode"})
+
+	insertLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ws_sink_write_duration_seconds",
+		Help:    "Latency of Sink.Write calls (of CopyFrom calls in copy mode).",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"mode"})
+
+	batchSizeObserved = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ws_batch_size",
+		Help:    "Size of batches flushed in bulk/copy mode.",
+		Buckets: []float64{1, 10, 100, 500, 1_000, 2_500, 5_000, 10_000},
+	}, []string{"mode"})
+
+	channelOccupancy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ws_channel_occupancy",
+		Help: "Current number of buffered messages awaiting a worker.",
+	}, []string{"mode"})
+
+	activeConnections = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ws_active_connections",
+		Help: "Currently open publishing websocket connections; subscribers are not counted.",
+	}, []string{"mode"})
+)
+
+// registerMetricsRoute exposes /metrics on app via promhttp.
+func registerMetricsRoute(app *fiber.App) {
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+}
+
+// observeWrite records the latency of a Sink.Write call for mode.
+func observeWrite(mode string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	insertLatency.WithLabelValues(mode).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// reportChannelOccupancy periodically samples len(ch) until ctx is done.
+func reportChannelOccupancy(ctx context.Context, mode string, ch chan Message) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			channelOccupancy.WithLabelValues(mode).Set(0)
+			return
+		case <-ticker.C:
+			channelOccupancy.WithLabelValues(mode).Set(float64(len(ch)))
+		}
+	}
+}
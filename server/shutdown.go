@@ -0,0 +1,75 @@
+// ============================================
+// Shutdown & backpressure
+//
+// rootContext is cancelled on SIGINT/SIGTERM and threaded through every
+// worker and websocket handler so a signal triggers an orderly shutdown:
+// stop accepting new connections, let in-flight websocket readers exit,
+// close the work channel, let workers drain and flush, then exit.
+// ============================================
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/websocket/v2"
+)
+
+// backpressureTimeout bounds how long a websocket handler will wait for the
+// work channel to accept a message before it gives up and tells the client
+// to back off, rather than blocking forever.
+const backpressureTimeout = 200 * time.Millisecond
+
+// rootContext returns a context cancelled on SIGINT/SIGTERM.
+func rootContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		log.Printf("received %s, shutting down", sig)
+		cancel()
+	}()
+
+	return ctx, cancel
+}
+
+// unblockOnShutdown ties c's read deadline to ctx so a handler blocked in
+// c.ReadMessage() on an idle connection doesn't keep wsWG non-zero forever
+// when ctx is cancelled: app.Shutdown() stops new connections but doesn't
+// force-close already-hijacked websocket conns. Call the returned stop func
+// once the handler returns normally, to avoid leaking the watcher goroutine.
+func unblockOnShutdown(ctx context.Context, c *websocket.Conn) (stop func()) {
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.SetReadDeadline(time.Now())
+		case <-stopped:
+		}
+	}()
+	return func() { close(stopped) }
+}
+
+// sendWithBackpressure enqueues msg on ch. If ch is still full after
+// backpressureTimeout, it sends the client a "server overloaded" close
+// frame instead of blocking indefinitely, and reports false so the caller
+// stops reading from this connection.
+func sendWithBackpressure(ctx context.Context, c *websocket.Conn, ch chan<- Message, msg Message) bool {
+	select {
+	case ch <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-time.After(backpressureTimeout):
+		closeMsg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "server overloaded")
+		_ = c.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+		return false
+	}
+}
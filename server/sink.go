@@ -0,0 +1,50 @@
+// ============================================
+// Sink abstraction
+//
+// A Sink is anything that can durably accept a batch of messages.
+// runNaive/runBuffered/runBulk write to whichever Sink is selected via
+// -sink=, independent of the -mode= ingestion strategy, so relational
+// and streaming backends can be benchmarked on identical traffic.
+// ============================================
+
+package main
+
+import (
+	"context"
+	"log"
+)
+
+// Message is a single ingested websocket payload, routed by Topic. Seq is
+// its WAL sequence number when durability is enabled, else zero.
+type Message struct {
+	Seq     uint64
+	Topic   string
+	Payload string
+}
+
+// Sink accepts batches of messages and is responsible for getting them to
+// whatever backend it wraps. Write may buffer internally; Flush forces any
+// buffered data out. Close releases underlying resources and should be
+// preceded by a final Flush.
+type Sink interface {
+	Write(ctx context.Context, msgs []Message) error
+	Flush(ctx context.Context) error
+	Close() error
+}
+
+// newSink constructs the Sink selected by -sink=.
+func newSink(name string) Sink {
+	switch name {
+	case "postgres", "":
+		return newPostgresSink()
+	case "kafka":
+		return newKafkaSink()
+	case "nats":
+		return newNATSSink()
+	case "stdout":
+		return newStdoutSink()
+	default:
+		log.Fatalf("unknown sink: %s", name)
+		return nil
+	}
+}
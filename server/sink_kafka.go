@@ -0,0 +1,51 @@
+// ============================================
+// Kafka sink — publishes each message to a topic-named Kafka topic,
+// falling back to "messages" for untopicked traffic
+// ============================================
+
+package main
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const defaultKafkaTopic = "messages"
+
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+func newKafkaSink() *kafkaSink {
+	return &kafkaSink{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP("localhost:9092"),
+			Balancer: &kafka.LeastBytes{},
+		},
+	}
+}
+
+func (s *kafkaSink) Write(ctx context.Context, msgs []Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	kmsgs := make([]kafka.Message, len(msgs))
+	for i, m := range msgs {
+		topic := m.Topic
+		if topic == "" {
+			topic = defaultKafkaTopic
+		}
+		kmsgs[i] = kafka.Message{Topic: topic, Value: []byte(m.Payload)}
+	}
+	return s.writer.WriteMessages(ctx, kmsgs...)
+}
+
+func (s *kafkaSink) Flush(ctx context.Context) error {
+	return nil
+}
+
+func (s *kafkaSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,48 @@
+// ============================================
+// NATS sink — publishes each message to its topic's subject, falling back
+// to "messages" for untopicked traffic
+// ============================================
+
+package main
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+const defaultNATSSubject = "messages"
+
+type natsSink struct {
+	conn *nats.Conn
+}
+
+func newNATSSink() *natsSink {
+	conn, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		panic(err)
+	}
+	return &natsSink{conn: conn}
+}
+
+func (s *natsSink) Write(ctx context.Context, msgs []Message) error {
+	for _, m := range msgs {
+		subject := m.Topic
+		if subject == "" {
+			subject = defaultNATSSubject
+		}
+		if err := s.conn.Publish(subject, []byte(m.Payload)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *natsSink) Flush(ctx context.Context) error {
+	return s.conn.FlushWithContext(ctx)
+}
+
+func (s *natsSink) Close() error {
+	s.conn.Close()
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildBulkInsert(t *testing.T) {
+	batch := []Message{
+		{Topic: "a", Payload: "one"},
+		{Topic: "b", Payload: "two"},
+		{Topic: "c", Payload: "three"},
+	}
+
+	query, args := buildBulkInsert(batch)
+
+	const want = "INSERT INTO messages (topic, payload) VALUES ($1, $2),($3, $4),($5, $6)"
+	if query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+
+	wantArgs := []any{"a", "one", "b", "two", "c", "three"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestBuildBulkInsertSingleRow(t *testing.T) {
+	query, args := buildBulkInsert([]Message{{Topic: "x", Payload: "y"}})
+
+	const want = "INSERT INTO messages (topic, payload) VALUES ($1, $2)"
+	if query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if !reflect.DeepEqual(args, []any{"x", "y"}) {
+		t.Errorf("args = %v, want [x y]", args)
+	}
+}
@@ -0,0 +1,50 @@
+// ============================================
+// Stdout sink — dumps each message as a line, useful as a zero-dependency
+// baseline to isolate websocket/channel overhead from backend latency
+// ============================================
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// stdoutSink is written from workerCount worker goroutines concurrently in
+// buffered/bulk modes, so access to the shared bufio.Writer is serialized by
+// mu.
+type stdoutSink struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func newStdoutSink() *stdoutSink {
+	return &stdoutSink{w: bufio.NewWriter(os.Stdout)}
+}
+
+func (s *stdoutSink) Write(ctx context.Context, msgs []Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range msgs {
+		if _, err := fmt.Fprintf(s.w, "[%s] %s\n", m.Topic, m.Payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stdoutSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Flush()
+}
+
+func (s *stdoutSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Flush()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestWALCompactAdvancesOverContiguousCommits(t *testing.T) {
+	w := openWAL(t.TempDir(), "sync")
+	defer w.close()
+
+	var seqs []uint64
+	for i := 0; i < 3; i++ {
+		seqs = append(seqs, w.append(Message{Topic: "t", Payload: "p"}))
+	}
+
+	// Commit the first two seqs but not the third: compact should advance
+	// truncateFrom up to (not past) the gap and stop there.
+	w.markCommitted(seqs[0])
+	w.markCommitted(seqs[1])
+	w.compact()
+
+	if w.truncateFrom != seqs[2] {
+		t.Fatalf("truncateFrom = %d, want %d (stalled on uncommitted seq)", w.truncateFrom, seqs[2])
+	}
+
+	// Committing the gap lets compaction advance past it too.
+	w.markCommitted(seqs[2])
+	w.compact()
+
+	if want := seqs[2] + 1; w.truncateFrom != want {
+		t.Fatalf("truncateFrom = %d, want %d", w.truncateFrom, want)
+	}
+	if first, err := w.log.FirstIndex(); err != nil {
+		t.Fatalf("FirstIndex: %v", err)
+	} else if first != 0 && first < seqs[2] {
+		t.Fatalf("FirstIndex = %d, want >= %d after truncation", first, seqs[2])
+	}
+}
+
+func TestWALReplayPreservesOrder(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openWAL(dir, "sync")
+	want := []Message{
+		{Topic: "a", Payload: "one"},
+		{Topic: "b", Payload: "two"},
+		{Topic: "c", Payload: "three"},
+	}
+	for i := range want {
+		want[i].Seq = w.append(want[i])
+	}
+	if err := w.close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	reopened := openWAL(dir, "sync")
+	defer reopened.close()
+
+	var got []Message
+	n := reopened.replay(func(m Message) { got = append(got, m) })
+
+	if n != len(want) {
+		t.Fatalf("replayed %d messages, want %d", n, len(want))
+	}
+	for i, m := range got {
+		if m != want[i] {
+			t.Errorf("replay[%d] = %+v, want %+v", i, m, want[i])
+		}
+	}
+}